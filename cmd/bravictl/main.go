@@ -0,0 +1,115 @@
+// Command bravictl is a small CLI for bravia2denon's admin HTTP API,
+// analogous to yggdrasilctl: point it at a running daemon and ask for
+// status, change the volume, or tail its event stream.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	addr := flag.String("addr", "http://localhost:8080", "bravia2denon admin API address")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "status":
+		err = cmdStatus(*addr)
+	case "set-volume":
+		err = cmdSetVolume(*addr, args[1:])
+	case "tail-events":
+		err = cmdTailEvents(*addr)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bravictl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bravictl [-addr url] <status|set-volume <level>|tail-events>")
+}
+
+func cmdStatus(addr string) error {
+	resp, err := http.Get(addr + "/status")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	var status map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(status))
+	for k := range status {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%-16s %v\n", k, status[k])
+	}
+	return nil
+}
+
+func cmdSetVolume(addr string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bravictl set-volume <level>")
+	}
+	level, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid level %q: %w", args[0], err)
+	}
+
+	body := strings.NewReader(fmt.Sprintf(`{"level":%d}`, level))
+	resp, err := http.Post(addr+"/volume", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+func cmdTailEvents(addr string) error {
+	resp, err := http.Get(addr + "/events")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			fmt.Println(data)
+		}
+	}
+	return scanner.Err()
+}
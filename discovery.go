@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Device discovery. Rather than hardcoding the Sony Bravia REST base URL and
+// the Denon telnet address, we look them up on the LAN with SSDP (and, for
+// devices that don't answer SSDP, a best-effort mDNS probe), then cache
+// whatever we found so a restart doesn't pay the discovery cost again. This
+// means the tool keeps working across DHCP lease changes and doesn't need
+// source edits per household.
+
+const (
+	ssdpMulticastAddr = "239.255.255.250:1900"
+	ssdpBraviaST      = "urn:schemas-sony-com:service:ScalarWebAPI:1"
+	ssdpRendererST    = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	mdnsMulticastAddr = "224.0.0.251:5353"
+	denonTelnetPort   = "23"
+
+	// defaultAuthPSK seeds a fresh cache file. The Sony pre-shared key isn't
+	// advertised over the LAN (it's set up via the Bravia's "IP Control"
+	// menu), so the user has to edit the cache file once after first run.
+	defaultAuthPSK = "0000"
+)
+
+// deviceConfig holds everything the rest of the program needs to talk to the
+// discovered (or cached) devices.
+type deviceConfig struct {
+	SonyAPIURL string `json:"sonyAPIURL"`
+	DenonAddr  string `json:"denonAddr"`
+	AuthPSK    string `json:"authPSK"`
+}
+
+func (c deviceConfig) complete() bool {
+	return c.SonyAPIURL != "" && c.DenonAddr != ""
+}
+
+// reachable reports whether both devices in c can actually be dialed right
+// now, so a cache left over from before a DHCP lease change doesn't get
+// trusted forever.
+func (c deviceConfig) reachable() bool {
+	if !dialReachable(c.DenonAddr, 2*time.Second) {
+		return false
+	}
+	host, ok := hostFromURL(c.SonyAPIURL)
+	if !ok {
+		return false
+	}
+	return dialReachable(net.JoinHostPort(host, "80"), 2*time.Second)
+}
+
+func dialReachable(addr string, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func hostFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+	return u.Hostname(), true
+}
+
+// discoverDevices returns a usable deviceConfig, preferring a cached result
+// from a previous run and falling back to live SSDP/mDNS discovery - also
+// the cached result's own fallback, if the devices it names have since
+// moved (e.g. a DHCP lease change) and it's no longer reachable.
+func discoverDevices() (deviceConfig, error) {
+	if cfg, err := loadCachedConfig(); err == nil && cfg.complete() {
+		if cfg.reachable() {
+			fmt.Println("Using cached device config from", cacheFilePath())
+			return cfg, nil
+		}
+		fmt.Println("Cached device config at", cacheFilePath(), "is no longer reachable, rediscovering...")
+	}
+
+	fmt.Println("Searching for Bravia and Denon devices via SSDP...")
+	cfg, ssdpErr := ssdpDiscover()
+
+	if !cfg.complete() {
+		fmt.Println("SSDP discovery incomplete, falling back to mDNS...")
+		mdnsCfg, mdnsErr := mdnsDiscover()
+		cfg = cfg.mergedWith(mdnsCfg)
+		if !cfg.complete() {
+			return cfg, fmt.Errorf("discovery: could not find both devices (sony=%q denon=%q); ssdp error: %v; mdns error: %v",
+				cfg.SonyAPIURL, cfg.DenonAddr, ssdpErr, mdnsErr)
+		}
+	}
+
+	if cfg.AuthPSK == "" {
+		cfg.AuthPSK = defaultAuthPSK
+	}
+
+	if err := saveCachedConfig(cfg); err != nil {
+		fmt.Println("Warning: could not cache discovered devices:", err)
+	}
+
+	return cfg, nil
+}
+
+// mergedWith fills in any fields missing from c using non-empty fields from other.
+func (c deviceConfig) mergedWith(other deviceConfig) deviceConfig {
+	if c.SonyAPIURL == "" {
+		c.SonyAPIURL = other.SonyAPIURL
+	}
+	if c.DenonAddr == "" {
+		c.DenonAddr = other.DenonAddr
+	}
+	if c.AuthPSK == "" {
+		c.AuthPSK = other.AuthPSK
+	}
+	return c
+}
+
+func cacheFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = "."
+	}
+	return filepath.Join(configDir, "bravia2denon", "devices.json")
+}
+
+func loadCachedConfig() (deviceConfig, error) {
+	data, err := os.ReadFile(cacheFilePath())
+	if err != nil {
+		return deviceConfig{}, err
+	}
+	var cfg deviceConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return deviceConfig{}, err
+	}
+	return cfg, nil
+}
+
+func saveCachedConfig(cfg deviceConfig) error {
+	path := cacheFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// --- SSDP ---
+
+type ssdpResponse struct {
+	Location   string
+	SearchType string
+	Server     string
+	RemoteIP   string
+}
+
+// ssdpDiscover sends an M-SEARCH for both the Sony ScalarWebAPI service and
+// generic UPnP media renderers (which covers Denon AVRs), then inspects each
+// responder's device description to tell them apart.
+func ssdpDiscover() (deviceConfig, error) {
+	var cfg deviceConfig
+
+	responses, err := ssdpSearch(ssdpBraviaST, 3*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+	for _, resp := range responses {
+		if cfg.SonyAPIURL == "" {
+			if apiURL, ok := sonyAPIURLFromLocation(resp.Location); ok {
+				cfg.SonyAPIURL = apiURL
+			}
+		}
+	}
+
+	renderers, err := ssdpSearch(ssdpRendererST, 3*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+	for _, resp := range renderers {
+		device, err := fetchDeviceDescription(resp.Location)
+		if err != nil {
+			continue
+		}
+		if cfg.SonyAPIURL == "" && strings.Contains(strings.ToLower(device.Device.Manufacturer), "sony") {
+			if apiURL, ok := sonyAPIURLFromLocation(resp.Location); ok {
+				cfg.SonyAPIURL = apiURL
+			}
+		}
+		if cfg.DenonAddr == "" && strings.Contains(strings.ToLower(device.Device.Manufacturer), "denon") {
+			if addr, ok := denonAddrFromLocation(resp.Location); ok {
+				cfg.DenonAddr = addr
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// ssdpSearch broadcasts a single M-SEARCH for searchTarget and collects
+// whatever responses arrive within timeout.
+func ssdpSearch(searchTarget string, timeout time.Duration) ([]ssdpResponse, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("ssdp: listen: %w", err)
+	}
+	defer conn.Close()
+
+	dst, err := net.ResolveUDPAddr("udp4", ssdpMulticastAddr)
+	if err != nil {
+		return nil, fmt.Errorf("ssdp: resolve multicast addr: %w", err)
+	}
+
+	request := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpMulticastAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: " + searchTarget + "\r\n\r\n"
+
+	if _, err := conn.WriteTo([]byte(request), dst); err != nil {
+		return nil, fmt.Errorf("ssdp: write: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	var responses []ssdpResponse
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			break // timeout, which is the normal way this loop ends
+		}
+		resp := parseSSDPResponse(buf[:n])
+		resp.SearchType = searchTarget
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			resp.RemoteIP = udpAddr.IP.String()
+		}
+		if resp.Location != "" {
+			responses = append(responses, resp)
+		}
+	}
+	return responses, nil
+}
+
+func parseSSDPResponse(data []byte) ssdpResponse {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	reader.ReadString('\n') // discard the "HTTP/1.1 200 OK" status line
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return ssdpResponse{}
+	}
+	return ssdpResponse{
+		Location: header.Get("Location"),
+		Server:   header.Get("Server"),
+	}
+}
+
+// upnpDeviceDescription is the handful of fields we care about from a
+// device's UPnP description XML.
+type upnpDeviceDescription struct {
+	Device struct {
+		DeviceType   string `xml:"deviceType"`
+		FriendlyName string `xml:"friendlyName"`
+		Manufacturer string `xml:"manufacturer"`
+	} `xml:"device"`
+}
+
+func fetchDeviceDescription(location string) (upnpDeviceDescription, error) {
+	var desc upnpDeviceDescription
+	resp, err := http.Get(location)
+	if err != nil {
+		return desc, err
+	}
+	defer resp.Body.Close()
+
+	if err := xml.NewDecoder(resp.Body).Decode(&desc); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
+
+func sonyAPIURLFromLocation(location string) (string, bool) {
+	host, ok := hostFromLocation(location)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("http://%s/sony/", host), true
+}
+
+func denonAddrFromLocation(location string) (string, bool) {
+	host, ok := hostFromLocation(location)
+	if !ok {
+		return "", false
+	}
+	return net.JoinHostPort(host, denonTelnetPort), true
+}
+
+func hostFromLocation(location string) (string, bool) {
+	u, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return "", false
+	}
+	host := u.URL.Hostname()
+	if host == "" {
+		return "", false
+	}
+	return host, true
+}
+
+// --- mDNS fallback ---
+//
+// Bravia and HEOS-equipped Denon receivers also answer mDNS queries for
+// "_sony-bravia._tcp.local." and "_heos-audio._tcp.local." respectively. We
+// don't need a full DNS message parser here: sending the query and noting
+// which multicast group member answers (and matching the query name back in
+// its raw bytes) is enough to recover the device's current IP address.
+func mdnsDiscover() (deviceConfig, error) {
+	var cfg deviceConfig
+
+	if ip, err := mdnsQuery("_sony-bravia._tcp.local."); err == nil {
+		cfg.SonyAPIURL = fmt.Sprintf("http://%s/sony/", ip)
+	}
+	if ip, err := mdnsQuery("_heos-audio._tcp.local."); err == nil {
+		cfg.DenonAddr = net.JoinHostPort(ip, denonTelnetPort)
+	}
+
+	if !cfg.complete() {
+		return cfg, fmt.Errorf("mdns: no response for missing device(s)")
+	}
+	return cfg, nil
+}
+
+// mdnsQuery sends a single PTR query for serviceName and returns the sender
+// IP of the first reply that echoes the queried name back.
+func mdnsQuery(serviceName string) (string, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return "", fmt.Errorf("mdns: listen: %w", err)
+	}
+	defer conn.Close()
+
+	dst, err := net.ResolveUDPAddr("udp4", mdnsMulticastAddr)
+	if err != nil {
+		return "", fmt.Errorf("mdns: resolve multicast addr: %w", err)
+	}
+
+	query := encodeMDNSQuery(serviceName)
+	if _, err := conn.WriteTo(query, dst); err != nil {
+		return "", fmt.Errorf("mdns: write: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 2048)
+	// Replies carry the name in DNS wire format (length-prefixed labels).
+	var encodedName bytes.Buffer
+	for _, label := range strings.Split(strings.TrimSuffix(serviceName, "."), ".") {
+		encodedName.WriteByte(byte(len(label)))
+		encodedName.WriteString(label)
+	}
+	nameBytes := encodedName.Bytes()
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return "", fmt.Errorf("mdns: no reply for %s", serviceName)
+		}
+		if !bytes.Contains(buf[:n], nameBytes) {
+			continue // multicast chatter for something else
+		}
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			return udpAddr.IP.String(), nil
+		}
+	}
+}
+
+// encodeMDNSQuery builds a minimal one-question DNS query packet asking for
+// the PTR record of name.
+func encodeMDNSQuery(name string) []byte {
+	var buf bytes.Buffer
+	// Header: ID=0, standard query, 1 question, 0 answers/authority/additional.
+	buf.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		buf.WriteByte(byte(len(label)))
+		buf.WriteString(label)
+	}
+	buf.WriteByte(0x00)           // end of name
+	buf.Write([]byte{0x00, 0x0c}) // QTYPE = PTR
+	buf.Write([]byte{0x00, 0x01}) // QCLASS = IN
+	return buf.Bytes()
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestDeviceConfigComplete(t *testing.T) {
+	cases := []struct {
+		cfg  deviceConfig
+		want bool
+	}{
+		{deviceConfig{}, false},
+		{deviceConfig{SonyAPIURL: "http://1.2.3.4/sony/"}, false},
+		{deviceConfig{DenonAddr: "1.2.3.4:23"}, false},
+		{deviceConfig{SonyAPIURL: "http://1.2.3.4/sony/", DenonAddr: "1.2.3.4:23"}, true},
+	}
+	for _, c := range cases {
+		if got := c.cfg.complete(); got != c.want {
+			t.Errorf("complete(%+v) = %v, want %v", c.cfg, got, c.want)
+		}
+	}
+}
+
+func TestDeviceConfigMergedWith(t *testing.T) {
+	partial := deviceConfig{SonyAPIURL: "http://1.2.3.4/sony/"}
+	other := deviceConfig{SonyAPIURL: "http://5.6.7.8/sony/", DenonAddr: "5.6.7.8:23", AuthPSK: "abcd"}
+
+	merged := partial.mergedWith(other)
+	if merged.SonyAPIURL != partial.SonyAPIURL {
+		t.Errorf("SonyAPIURL = %q, want the non-empty field kept (%q)", merged.SonyAPIURL, partial.SonyAPIURL)
+	}
+	if merged.DenonAddr != other.DenonAddr {
+		t.Errorf("DenonAddr = %q, want filled in from other (%q)", merged.DenonAddr, other.DenonAddr)
+	}
+	if merged.AuthPSK != other.AuthPSK {
+		t.Errorf("AuthPSK = %q, want filled in from other (%q)", merged.AuthPSK, other.AuthPSK)
+	}
+}
+
+func TestParseSSDPResponse(t *testing.T) {
+	raw := "HTTP/1.1 200 OK\r\n" +
+		"LOCATION: http://192.168.1.50:52323/dmr.xml\r\n" +
+		"SERVER: Linux/1.0 UPnP/1.0\r\n\r\n"
+
+	resp := parseSSDPResponse([]byte(raw))
+	if resp.Location != "http://192.168.1.50:52323/dmr.xml" {
+		t.Errorf("Location = %q, want the parsed header value", resp.Location)
+	}
+	if resp.Server != "Linux/1.0 UPnP/1.0" {
+		t.Errorf("Server = %q, want the parsed header value", resp.Server)
+	}
+}
+
+func TestParseSSDPResponseMalformed(t *testing.T) {
+	resp := parseSSDPResponse([]byte("not an HTTP response"))
+	if resp.Location != "" {
+		t.Errorf("Location = %q, want empty for malformed input", resp.Location)
+	}
+}
+
+func TestSonyAPIURLFromLocation(t *testing.T) {
+	url, ok := sonyAPIURLFromLocation("http://192.168.1.50:52323/sony/dd.xml")
+	if !ok || url != "http://192.168.1.50/sony/" {
+		t.Errorf("sonyAPIURLFromLocation = (%q, %v), want (\"http://192.168.1.50/sony/\", true)", url, ok)
+	}
+}
+
+func TestDenonAddrFromLocation(t *testing.T) {
+	addr, ok := denonAddrFromLocation("http://192.168.1.60:60006/description.xml")
+	if !ok || addr != "192.168.1.60:23" {
+		t.Errorf("denonAddrFromLocation = (%q, %v), want (\"192.168.1.60:23\", true)", addr, ok)
+	}
+}
+
+func TestHostFromURL(t *testing.T) {
+	host, ok := hostFromURL("http://192.168.1.50/sony/")
+	if !ok || host != "192.168.1.50" {
+		t.Errorf("hostFromURL = (%q, %v), want (\"192.168.1.50\", true)", host, ok)
+	}
+
+	if _, ok := hostFromURL("::not a url::"); ok {
+		t.Error("hostFromURL should fail on an unparseable URL")
+	}
+}
@@ -0,0 +1,206 @@
+// Command bravia2denon mirrors a Sony Bravia TV's volume onto a Denon AVR,
+// capped at a configurable maximum, so the TV remote stays the single volume
+// control for the room. It also exposes an HTTP admin API (see pkg/api) so
+// the cap can be changed, syncing paused, and state observed without
+// restarting the daemon.
+//
+// Device addresses, polling/backoff timing, the Denon zone allow-list, and
+// per-input profiles all come from a YAML config file (see pkg/config);
+// leaving "devices" empty in that file falls back to SSDP/mDNS discovery.
+//
+// Every configured device pair is synced concurrently in this one process,
+// each with its own engine and admin API; see config.DevicePair.Listen for
+// how each pair's API picks its listen address.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/api"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/bravia"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/config"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/denon"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/sonos"
+	syncengine "github.com/gpatola/Bravia2DenonVolumeController/pkg/sync"
+)
+
+const defaultDeviceName = "default"
+
+func main() {
+	listen := flag.String("listen", ":8080", "address for the admin HTTP API (used only when a single device pair is configured, or discovered, and its own \"listen\" is unset)")
+	configPath := flag.String("config", config.DefaultPath(), "path to config.yaml")
+	flag.Parse()
+
+	manager, err := loadOrInitConfig(*configPath)
+	if err != nil {
+		fmt.Println("Config error:", err)
+		return
+	}
+	if err := manager.Watch(); err != nil {
+		fmt.Println("Warning: config hot-reload disabled:", err)
+	}
+
+	devices, err := resolveDevices(manager.Current(), *listen)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, device := range devices {
+		wg.Add(1)
+		go func(device config.DevicePair) {
+			defer wg.Done()
+			runDevice(manager, device)
+		}(device)
+	}
+	wg.Wait()
+}
+
+// runDevice wires up and runs one device pair's sync engine and admin API
+// until its API server fails. It's meant to run in its own goroutine
+// alongside every other configured device pair's.
+func runDevice(manager *config.Manager, device config.DevicePair) {
+	if !device.Denon.ZoneAllowed("Main") {
+		fmt.Println("Main zone is not in the configured allow-list for", device.Name, "- nothing to sync.")
+		return
+	}
+
+	fmt.Println("Using Bravia at", device.Sony.APIURL, "and Denon at", device.Denon.Addr, "for", device.Name)
+
+	tv := bravia.NewClient(device.Sony.APIURL, device.Sony.AuthPSK)
+	avr := denon.NewClient(device.Denon.Addr, denon.WithReconnectBackoff(manager.Current().ReconnectBackoff.Duration))
+
+	capCurve := syncengine.NewMutableCapCurve(device.DefaultMaxVolume)
+	profiles := newProfileCurve(capCurve)
+	sinks := []syncengine.SinkConfig{{Sink: avr, Curve: profiles}}
+
+	if device.Sonos.ControlURL != "" {
+		sinks = append(sinks, syncengine.SinkConfig{
+			Sink:  sonos.NewClient(device.Sonos.ControlURL),
+			Curve: buildCurve(device.Sonos.Curve, device.DefaultMaxVolume),
+		})
+	}
+
+	engine := syncengine.New(tv, sinks...)
+	engine.SetPollInterval(manager.Current().PollInterval.Duration)
+
+	go watchInputProfiles(manager, device.Name, tv, avr, profiles)
+	go applyConfigUpdates(manager, device.Name, engine, capCurve)
+
+	go func() {
+		if err := engine.Run(); err != nil {
+			fmt.Println("Sync engine stopped for", device.Name, ":", err)
+		}
+	}()
+
+	server := api.NewServer(tv, avr, engine, capCurve)
+	fmt.Println("Admin API for", device.Name, "listening on", device.Listen)
+	if err := server.Run(device.Listen); err != nil {
+		fmt.Println("Admin API stopped for", device.Name, ":", err)
+	}
+}
+
+// buildCurve turns a config.CurveConfig into the syncengine.Curve it names,
+// using fallbackMax as the sink ceiling when the curve sets none of its own,
+// and a plain cap when Type is empty or not "linear"/"log".
+func buildCurve(cfg config.CurveConfig, fallbackMax int) syncengine.Curve {
+	sinkMax := cfg.SinkMax
+	if sinkMax == 0 {
+		sinkMax = fallbackMax
+	}
+	sourceMax := cfg.SourceMax
+	if sourceMax == 0 {
+		sourceMax = 100
+	}
+	switch cfg.Type {
+	case "linear":
+		return syncengine.LinearCurve{SourceMax: sourceMax, SinkMax: sinkMax}
+	case "log":
+		return syncengine.LogCurve{SourceMax: sourceMax, SinkMax: sinkMax}
+	default:
+		return syncengine.CapCurve{Max: sinkMax}
+	}
+}
+
+// resolveDevices returns every device pair to sync, assigning each its admin
+// API listen address: a pair's own Listen if set, otherwise defaultListen if
+// it's the only pair. A config with no devices falls back to one pair
+// synthesized from live SSDP/mDNS discovery, so an empty "devices" list
+// still works.
+func resolveDevices(cfg config.Config, defaultListen string) ([]config.DevicePair, error) {
+	if len(cfg.Devices) == 0 {
+		fmt.Println("Config has no devices, falling back to SSDP/mDNS discovery...")
+		discovered, err := discoverDevices()
+		if err != nil {
+			return nil, fmt.Errorf("device discovery failed: %w", err)
+		}
+		return []config.DevicePair{{
+			Name:             defaultDeviceName,
+			Sony:             config.SonyConfig{APIURL: discovered.SonyAPIURL, AuthPSK: discovered.AuthPSK},
+			Denon:            config.DenonConfig{Addr: discovered.DenonAddr},
+			DefaultMaxVolume: 40,
+			Listen:           defaultListen,
+		}}, nil
+	}
+
+	devices := make([]config.DevicePair, len(cfg.Devices))
+	copy(devices, cfg.Devices)
+
+	if len(devices) == 1 && devices[0].Listen == "" {
+		devices[0].Listen = defaultListen
+	}
+
+	seen := make(map[string]string, len(devices))
+	for i, device := range devices {
+		if device.Listen == "" {
+			return nil, fmt.Errorf("config: device %q has no \"listen\" address, and -listen can only be its default when it's the only configured device pair", device.Name)
+		}
+		if other, ok := seen[device.Listen]; ok {
+			return nil, fmt.Errorf("config: devices %q and %q both listen on %q", other, device.Name, device.Listen)
+		}
+		seen[device.Listen] = device.Name
+		devices[i] = device
+	}
+	return devices, nil
+}
+
+// applyConfigUpdates reacts to config hot-reloads by pushing the new poll
+// interval and default volume cap into the already-running engine.
+func applyConfigUpdates(manager *config.Manager, deviceName string, engine *syncengine.Engine, capCurve *syncengine.MutableCapCurve) {
+	for cfg := range manager.Subscribe() {
+		engine.SetPollInterval(cfg.PollInterval.Duration)
+		if device, ok := deviceByName(cfg, deviceName); ok {
+			capCurve.SetMax(device.DefaultMaxVolume)
+		}
+	}
+}
+
+func loadOrInitConfig(path string) (*config.Manager, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := writeStarterConfig(path); err != nil {
+			return nil, fmt.Errorf("config: writing starter file: %w", err)
+		}
+		fmt.Println("Wrote starter config to", path)
+	}
+	return config.NewManager(path)
+}
+
+func writeStarterConfig(path string) error {
+	starter := []byte(`# bravia2denon config - see pkg/config for the full schema.
+# Leave "devices" empty to auto-discover a single Bravia/Denon pair via SSDP/mDNS.
+# Listing more than one device pair syncs all of them concurrently in this
+# one process; each needs its own "listen" address for its admin API.
+pollInterval: 1s
+reconnectBackoff: 1s
+devices: []
+`)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, starter, 0o644)
+}
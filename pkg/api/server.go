@@ -0,0 +1,310 @@
+// Package api is an embedded HTTP admin/control surface for bravia2denon:
+// REST endpoints to inspect and override device state, plus an SSE stream so
+// something like Home Assistant or Node-RED can react to changes instead of
+// polling for them.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/bravia"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/denon"
+	syncengine "github.com/gpatola/Bravia2DenonVolumeController/pkg/sync"
+)
+
+// Status is a snapshot of both devices' power and volume state. It's what
+// GET /status returns and what GET /events streams whenever it changes.
+type Status struct {
+	TVOn           bool `json:"tvOn"`
+	TVVolume       int  `json:"tvVolume"`
+	TVMuted        bool `json:"tvMuted"`
+	DenonOn        bool `json:"denonOn"`
+	DenonVolume    int  `json:"denonVolume"`
+	DenonMuted     bool `json:"denonMuted"`
+	SyncPaused     bool `json:"syncPaused"`
+	MaxDenonVolume int  `json:"maxDenonVolume"`
+}
+
+// Config is the subset of engine configuration exposed over GET/PUT /config.
+type Config struct {
+	MaxDenonVolume int `json:"maxDenonVolume"`
+}
+
+// Server serves the admin API for one Bravia/Denon pair mirrored by Engine.
+type Server struct {
+	TV       *bravia.Client
+	Denon    *denon.Client
+	Engine   *syncengine.Engine
+	CapCurve *syncengine.MutableCapCurve
+
+	subsMu sync.Mutex
+	subs   map[chan Status]struct{}
+
+	lastMu sync.Mutex
+	last   Status
+}
+
+// NewServer builds a Server for the given devices, engine and cap curve
+// (the same MutableCapCurve instance passed to the engine's SinkConfig, so
+// PUT /config actually changes what the engine enforces).
+func NewServer(tv *bravia.Client, denonClient *denon.Client, engine *syncengine.Engine, capCurve *syncengine.MutableCapCurve) *Server {
+	return &Server{
+		TV:       tv,
+		Denon:    denonClient,
+		Engine:   engine,
+		CapCurve: capCurve,
+		subs:     make(map[chan Status]struct{}),
+	}
+}
+
+// Handler returns the API's http.Handler, useful for tests or for embedding
+// alongside other routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/volume", s.handleVolume)
+	mux.HandleFunc("/mute", s.handleMute)
+	mux.HandleFunc("/sync/pause", s.handleSyncPause)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/events", s.handleEvents)
+	return mux
+}
+
+// Run starts polling device status in the background and serves the API on
+// listen until it fails.
+func (s *Server) Run(listen string) error {
+	go s.pollStatus()
+	return http.ListenAndServe(listen, s.Handler())
+}
+
+// pollStatus periodically snapshots device state and broadcasts it to
+// /events subscribers whenever it changes.
+func (s *Server) pollStatus() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		status := s.snapshot()
+
+		s.lastMu.Lock()
+		changed := status != s.last
+		s.last = status
+		s.lastMu.Unlock()
+
+		if changed {
+			s.broadcast(status)
+		}
+	}
+}
+
+// snapshot reads current device state. Errors just leave the corresponding
+// fields at their last value; the devices being briefly unreachable
+// shouldn't crash the admin API.
+func (s *Server) snapshot() Status {
+	s.lastMu.Lock()
+	status := s.last
+	s.lastMu.Unlock()
+	status.SyncPaused = s.Engine.Paused()
+	status.MaxDenonVolume = s.CapCurve.Max()
+	if on, err := s.TV.IsOn(); err == nil {
+		status.TVOn = on
+	}
+	if volume, muted, err := s.TV.GetVolume(); err == nil {
+		status.TVVolume, status.TVMuted = volume, muted
+	}
+	if on, err := s.Denon.IsOn(); err == nil {
+		status.DenonOn = on
+	}
+	if volume, muted, err := s.Denon.GetVolume(); err == nil {
+		status.DenonVolume, status.DenonMuted = volume, muted
+	}
+	return status
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, s.snapshot())
+}
+
+// handleVolume reports or overrides the Denon's volume directly. A POST
+// pauses sync first (see /sync/pause), since otherwise the engine's next
+// poll tick would read the TV's volume back and revert the override.
+func (s *Server) handleVolume(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		volume, muted, err := s.Denon.GetVolume()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"level": volume, "muted": muted})
+
+	case http.MethodPost:
+		var req struct {
+			Level int `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Pause mirroring first, otherwise the engine's next poll tick
+		// reads the TV's volume back and overwrites this override.
+		s.Engine.SetPaused(true)
+		if err := s.Denon.SetVolume(req.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMute mutes or unmutes the Denon directly, pausing sync first for the
+// same reason handleVolume does.
+func (s *Server) handleMute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Mute bool `json:"mute"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Pause mirroring first, otherwise the engine's next poll tick reads the
+	// TV's volume back and undoes the mute/restore below.
+	s.Engine.SetPaused(true)
+
+	// The Denon sink has no dedicated mute in its minimal interface, so we
+	// mute by driving the volume to 0 and restoring whatever it was before.
+	if req.Mute {
+		volume, _, err := s.Denon.GetVolume()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		s.lastMu.Lock()
+		s.last.DenonVolume = volume
+		s.lastMu.Unlock()
+		if err := s.Denon.SetVolume(0); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	} else {
+		s.lastMu.Lock()
+		restore := s.last.DenonVolume
+		s.lastMu.Unlock()
+		if err := s.Denon.SetVolume(restore); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSyncPause(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]bool{"paused": s.Engine.Paused()})
+
+	case http.MethodPost:
+		var req struct {
+			Paused *bool `json:"paused"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Paused != nil {
+			s.Engine.SetPaused(*req.Paused)
+		} else {
+			s.Engine.SetPaused(!s.Engine.Paused())
+		}
+		writeJSON(w, map[string]bool{"paused": s.Engine.Paused()})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, Config{MaxDenonVolume: s.CapCurve.Max()})
+
+	case http.MethodPut:
+		var cfg Config
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.CapCurve.SetMax(cfg.MaxDenonVolume)
+		writeJSON(w, cfg)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleEvents streams Status updates as Server-Sent Events until the client
+// disconnects.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan Status, 8)
+	s.subsMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subsMu.Unlock()
+	defer func() {
+		s.subsMu.Lock()
+		delete(s.subs, ch)
+		s.subsMu.Unlock()
+	}()
+
+	for {
+		select {
+		case status := <-ch:
+			data, err := json.Marshal(status)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) broadcast(status Status) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- status:
+		default:
+			// Slow subscriber; drop rather than block the poller.
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
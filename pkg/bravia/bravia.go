@@ -0,0 +1,192 @@
+// Package bravia is a small client for the Sony Bravia REST ("sony/*") API,
+// used as a sync.VolumeSource: the TV's own volume and power state are the
+// thing everything else gets mirrored to.
+package bravia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Client talks to a single Bravia TV over its local REST API.
+type Client struct {
+	APIURL string // e.g. "http://192.168.20.20/sony/"
+	PSK    string // X-Auth-PSK value configured on the TV
+}
+
+// NewClient returns a Client for the TV at apiURL, authenticating with psk.
+func NewClient(apiURL, psk string) *Client {
+	return &Client{APIURL: apiURL, PSK: psk}
+}
+
+/* Example API call:
+
+	curl -H "Content-Type: application/json" -H "X-Auth-PSK: 1234" -X POST -d \
+    '{"id": 20, "method": "getPowerStatus", "id": 55, "params": [{"status": false}], "version": "1.0"}'  http://192.168.20.20/sony/system
+	{"result":[{"status":"active"}],"id":55}
+
+*/
+
+// IsOn reports whether the TV is powered on.
+func (c *Client) IsOn() (bool, error) {
+
+	requestBody := map[string]interface{}{
+		"method":  "getPowerStatus",
+		"id":      50,
+		"params":  []map[string]bool{},
+		"version": "1.0",
+	}
+	respBody, err := c.doPost("system", requestBody)
+	if err != nil {
+		return false, err
+	}
+
+	var respMap map[string]interface{}
+	if err := json.Unmarshal(respBody, &respMap); err != nil {
+		return false, err
+	}
+
+	resultArr, ok := respMap["result"].([]interface{})
+	if !ok || len(resultArr) == 0 {
+		return false, fmt.Errorf("invalid response: %v", respMap)
+	}
+
+	result, ok := resultArr[0].(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("invalid result format")
+	}
+
+	status, ok := result["status"].(string)
+	if !ok {
+		return false, fmt.Errorf("status not found")
+	}
+
+	return status == "active", nil
+}
+
+/* API example:
+
+   curl -H "Content-Type: application/json" -H "X-Auth-PSK: 1234" -X POST -d \
+   '{"method": "getVolumeInformation", "id": 33, "params": [], "version": "1.0"}'  http://192.168.20.20/sony/audio
+   {"result":[[{"target":"speaker","volume":3,"mute":false,"maxVolume":100,"minVolume":0}]],"id":33}
+
+*/
+
+// GetVolume returns the speaker volume and whether it's currently muted.
+func (c *Client) GetVolume() (int, bool, error) {
+
+	requestBody := map[string]interface{}{
+		"method":  "getVolumeInformation",
+		"id":      33,
+		"params":  []map[string]bool{},
+		"version": "1.0",
+	}
+	respBody, err := c.doPost("audio", requestBody)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var respMap map[string]interface{}
+	if err := json.Unmarshal(respBody, &respMap); err != nil {
+		return 0, false, err
+	}
+
+	resultArr, ok := respMap["result"].([]interface{})
+	if !ok || len(resultArr) == 0 {
+		return 0, false, fmt.Errorf("invalid response: %v", respMap)
+	}
+
+	volumeInfoArr, ok := resultArr[0].([]interface{})
+	if !ok || len(volumeInfoArr) == 0 {
+		return 0, false, fmt.Errorf("invalid volume info format")
+	}
+
+	volumeInfo, ok := volumeInfoArr[0].(map[string]interface{})
+	if !ok {
+		return 0, false, fmt.Errorf("invalid volume info structure")
+	}
+
+	volumeFloat, ok := volumeInfo["volume"].(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("volume not found or invalid type")
+	}
+
+	mute, ok := volumeInfo["mute"].(bool)
+	if !ok {
+		return 0, false, fmt.Errorf("mute status not found or invalid type")
+	}
+
+	return int(volumeFloat), mute, nil
+}
+
+/* API example:
+
+   curl -H "Content-Type: application/json" -H "X-Auth-PSK: 1234" -X POST -d \
+   '{"method": "getPlayingContentInfo", "id": 103, "params": [], "version": "1.0"}'  http://192.168.20.20/sony/avContent
+   {"result":[{"uri":"extInput:hdmi?port=2","title":"HDMI 2","source":"extInput:hdmi"}],"id":103}
+
+*/
+
+// CurrentInput returns the title of the TV's active input or app, e.g.
+// "HDMI 2" or "Netflix", used to select a per-input sync profile.
+func (c *Client) CurrentInput() (string, error) {
+
+	requestBody := map[string]interface{}{
+		"method":  "getPlayingContentInfo",
+		"id":      103,
+		"params":  []map[string]bool{},
+		"version": "1.0",
+	}
+	respBody, err := c.doPost("avContent", requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	var respMap map[string]interface{}
+	if err := json.Unmarshal(respBody, &respMap); err != nil {
+		return "", err
+	}
+
+	resultArr, ok := respMap["result"].([]interface{})
+	if !ok || len(resultArr) == 0 {
+		return "", fmt.Errorf("invalid response: %v", respMap)
+	}
+
+	result, ok := resultArr[0].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid result format")
+	}
+
+	if title, ok := result["title"].(string); ok && title != "" {
+		return title, nil
+	}
+	if source, ok := result["source"].(string); ok && source != "" {
+		return source, nil
+	}
+	return "", fmt.Errorf("input info not found")
+}
+
+// doPost POSTs body as JSON to the given sony/* endpoint and returns the raw response.
+func (c *Client) doPost(endpoint string, body interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", c.APIURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Auth-PSK", c.PSK)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,185 @@
+// Package config loads bravia2denon's YAML config file - polling interval,
+// reconnect backoff, Denon zone allow-list and per-Bravia-input sync
+// profiles for one or more device pairs - and can watch it for changes so
+// the daemon picks up edits without restarting.
+//
+// main runs every configured device pair concurrently in one process, each
+// with its own sync engine and admin API; see DevicePair.Listen.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the full contents of config.yaml.
+type Config struct {
+	PollInterval     Duration     `yaml:"pollInterval"`
+	ReconnectBackoff Duration     `yaml:"reconnectBackoff"`
+	Devices          []DevicePair `yaml:"devices"`
+}
+
+// DevicePair is one Bravia TV mirrored onto one Denon AVR, synced by its own
+// engine and served by its own admin API.
+type DevicePair struct {
+	Name             string         `yaml:"name"`
+	Sony             SonyConfig     `yaml:"sony"`
+	Denon            DenonConfig    `yaml:"denon"`
+	Profiles         []InputProfile `yaml:"profiles"`
+	DefaultMaxVolume int            `yaml:"defaultMaxVolume"`
+	// Listen is the address this pair's admin API listens on, e.g. ":8081".
+	// Required when more than one device pair is configured, since they
+	// can't all share the -listen flag's address; optional (falling back to
+	// -listen) when there's only one.
+	Listen string `yaml:"listen"`
+	// Sonos, if set, syncs onto a Sonos player as a second sink alongside
+	// the Denon AVR.
+	Sonos SonosConfig `yaml:"sonos"`
+}
+
+// SonosConfig adds a Sonos player as a second syncengine.VolumeSink for a
+// device pair, controlled via pkg/sonos.
+type SonosConfig struct {
+	// ControlURL is the player's RenderingControl control endpoint, e.g.
+	// "http://192.168.1.50:1400/MediaRenderer/RenderingControl/Control".
+	// Leaving it empty skips the Sonos sink entirely.
+	ControlURL string      `yaml:"controlURL"`
+	Curve      CurveConfig `yaml:"curve"`
+}
+
+// CurveConfig picks how a sink's volume is derived from the source's, e.g.
+// rescaling a TV's 0-100 onto an AVR's 0-98 instead of just capping it -
+// see pkg/sync's Curve implementations.
+type CurveConfig struct {
+	Type      string `yaml:"type"`      // "cap" (default), "linear", or "log"
+	SourceMax int    `yaml:"sourceMax"` // source volume ceiling curves scale from; defaults to 100
+	SinkMax   int    `yaml:"sinkMax"`   // sink volume ceiling curves scale to; defaults to the device's defaultMaxVolume
+}
+
+// SonyConfig addresses and authenticates against a Bravia TV.
+type SonyConfig struct {
+	APIURL  string `yaml:"apiURL"`
+	AuthPSK string `yaml:"authPSK"`
+}
+
+// DenonConfig addresses a Denon AVR and says which of its zones this tool
+// may touch.
+type DenonConfig struct {
+	Addr string `yaml:"addr"`
+	// Zones is an allow-list of zone names (e.g. "Main", "Z2", "Z3") this
+	// device pair may sync. Empty means "Main" only, since that's the only
+	// zone denon.Client currently controls.
+	Zones []string `yaml:"zones"`
+}
+
+// InputProfile routes one Bravia input/app to a Denon source with its own
+// volume cap and trim, switching automatically as the user changes HDMI
+// input or opens a different app.
+type InputProfile struct {
+	Input       string  `yaml:"input"`       // Bravia input/app title, e.g. "HDMI 2" or "Netflix"
+	DenonSource string  `yaml:"denonSource"` // Denon SI source to switch to, e.g. "GAME"
+	MaxVolume   int     `yaml:"maxVolume"`   // defaults to the device's defaultMaxVolume when left at 0
+	TrimDB      float64 `yaml:"trimDB"`
+}
+
+// ZoneAllowed reports whether zone may be synced under this config. Only
+// "Main" (the default when Zones is empty) is currently wired up in
+// denon.Client; Z2/Z3 entries are accepted here so they're ready once that
+// lands, but won't do anything yet.
+func (d DenonConfig) ZoneAllowed(zone string) bool {
+	if len(d.Zones) == 0 {
+		return zone == "" || strings.EqualFold(zone, "Main")
+	}
+	for _, z := range d.Zones {
+		if strings.EqualFold(z, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileFor returns the profile matching the given Bravia input/app title,
+// or nil if none matches.
+func (d DevicePair) ProfileFor(input string) *InputProfile {
+	for i := range d.Profiles {
+		if strings.EqualFold(d.Profiles[i].Input, input) {
+			return &d.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// Duration wraps time.Duration so it can be written as "1s"/"500ms" in YAML
+// instead of a raw integer of nanoseconds.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("config: invalid duration %q: %w", s, err)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.Duration.String(), nil
+}
+
+// DefaultPath returns the default config file location,
+// ~/.config/bravia2denon/config.yaml (or the platform equivalent).
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "bravia2denon", "config.yaml")
+}
+
+// Load reads and parses the config file at path, applying defaults for
+// anything left unset.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	cfg.applyDefaults()
+	return cfg, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.PollInterval.Duration == 0 {
+		c.PollInterval = Duration{time.Second}
+	}
+	if c.ReconnectBackoff.Duration == 0 {
+		c.ReconnectBackoff = Duration{time.Second}
+	}
+	for i := range c.Devices {
+		if c.Devices[i].DefaultMaxVolume == 0 {
+			c.Devices[i].DefaultMaxVolume = 40
+		}
+		for j := range c.Devices[i].Profiles {
+			if c.Devices[i].Profiles[j].MaxVolume == 0 {
+				c.Devices[i].Profiles[j].MaxVolume = c.Devices[i].DefaultMaxVolume
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import "testing"
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := Config{
+		Devices: []DevicePair{
+			{Name: "living-room"},
+			{Name: "bedroom", DefaultMaxVolume: 60, Profiles: []InputProfile{{Input: "Netflix", MaxVolume: 50}}},
+		},
+	}
+	cfg.applyDefaults()
+
+	if cfg.PollInterval.Duration == 0 {
+		t.Error("PollInterval not defaulted")
+	}
+	if cfg.ReconnectBackoff.Duration == 0 {
+		t.Error("ReconnectBackoff not defaulted")
+	}
+	if got := cfg.Devices[0].DefaultMaxVolume; got != 40 {
+		t.Errorf("Devices[0].DefaultMaxVolume = %d, want 40", got)
+	}
+	if got := cfg.Devices[1].DefaultMaxVolume; got != 60 {
+		t.Errorf("Devices[1].DefaultMaxVolume = %d, want 60 (explicit value should survive)", got)
+	}
+	if got := cfg.Devices[1].Profiles[0].MaxVolume; got != 50 {
+		t.Errorf("explicit profile MaxVolume overwritten: got %d, want 50", got)
+	}
+}
+
+func TestApplyDefaultsInheritsProfileMaxVolume(t *testing.T) {
+	cfg := Config{
+		Devices: []DevicePair{
+			{Name: "living-room", DefaultMaxVolume: 55, Profiles: []InputProfile{{Input: "HDMI 2"}}},
+		},
+	}
+	cfg.applyDefaults()
+
+	if got := cfg.Devices[0].Profiles[0].MaxVolume; got != 55 {
+		t.Errorf("profile MaxVolume = %d, want inherited 55", got)
+	}
+}
+
+func TestProfileFor(t *testing.T) {
+	device := DevicePair{Profiles: []InputProfile{
+		{Input: "Netflix", DenonSource: "NET"},
+		{Input: "HDMI 2", DenonSource: "GAME"},
+	}}
+
+	if p := device.ProfileFor("netflix"); p == nil || p.DenonSource != "NET" {
+		t.Errorf("ProfileFor(\"netflix\") = %v, want Netflix profile (case-insensitive match)", p)
+	}
+	if p := device.ProfileFor("HDMI 3"); p != nil {
+		t.Errorf("ProfileFor(\"HDMI 3\") = %v, want nil", p)
+	}
+}
+
+func TestZoneAllowed(t *testing.T) {
+	empty := DenonConfig{}
+	if !empty.ZoneAllowed("Main") {
+		t.Error("empty Zones should allow Main")
+	}
+	if empty.ZoneAllowed("Z2") {
+		t.Error("empty Zones should not allow Z2")
+	}
+
+	withZones := DenonConfig{Zones: []string{"Main", "z2"}}
+	if !withZones.ZoneAllowed("Z2") {
+		t.Error("Zones match should be case-insensitive")
+	}
+	if withZones.ZoneAllowed("Z3") {
+		t.Error("Z3 not in allow-list should be rejected")
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Manager holds the most recently loaded Config and can watch its file for
+// edits, reloading and notifying subscribers without the caller needing to
+// restart.
+type Manager struct {
+	path string
+
+	mu      sync.RWMutex
+	current Config
+
+	subsMu sync.Mutex
+	subs   map[chan Config]struct{}
+}
+
+// NewManager loads path once and returns a Manager around it.
+func NewManager(path string) (*Manager, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		path:    path,
+		current: cfg,
+		subs:    make(map[chan Config]struct{}),
+	}, nil
+}
+
+// Current returns the most recently loaded config.
+func (m *Manager) Current() Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Subscribe returns a channel that receives the new Config every time the
+// file is successfully reloaded.
+func (m *Manager) Subscribe() <-chan Config {
+	ch := make(chan Config, 1)
+	m.subsMu.Lock()
+	m.subs[ch] = struct{}{}
+	m.subsMu.Unlock()
+	return ch
+}
+
+// Watch starts watching the config file's directory (watching the file
+// itself misses the remove+recreate most editors do on save) and reloads
+// Current whenever it changes. The watcher runs until the process exits.
+func (m *Manager) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config: watch: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(m.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("config: watch: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(m.path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				m.reload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Println("config: watch error:", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (m *Manager) reload() {
+	cfg, err := Load(m.path)
+	if err != nil {
+		fmt.Println("config: reload failed, keeping previous config:", err)
+		return
+	}
+
+	m.mu.Lock()
+	m.current = cfg
+	m.mu.Unlock()
+
+	fmt.Println("config: reloaded from", m.path)
+
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+	for ch := range m.subs {
+		select {
+		case ch <- cfg:
+		default:
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Package denon is a client for the Denon AVR telnet control protocol:
+// https://assets.denon.com/documentmaster/uk/avr1713_avr1613_protocol_v860.pdf
+//
+// The receiver pushes unsolicited status frames over the same telnet
+// connection whenever its state changes from any source (remote, front
+// panel, another controller), so a client that dials in, sends one command
+// and reads one line back will eventually read the wrong reply. Client keeps
+// a single connection open, reads it continuously in the background, and
+// correlates queries with their replies by command prefix so pushes and
+// query responses can't be confused with each other.
+package denon
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	syncengine "github.com/gpatola/Bravia2DenonVolumeController/pkg/sync"
+)
+
+// Event is an unsolicited status frame from the receiver, e.g. "MV45" or
+// "Z2ON", that wasn't the reply to an in-flight query.
+type Event struct {
+	Raw string
+}
+
+// Client is a persistent connection to a Denon AVR's main zone.
+type Client struct {
+	addr string
+
+	mu   sync.Mutex // guards conn; writes are serialized through it too
+	conn net.Conn
+
+	inflightMu sync.Mutex
+	inflight   map[string][]chan string // keyed by command prefix, e.g. "MV", "PW"; FIFO per prefix
+
+	events chan Event
+
+	reconnectBackoff time.Duration
+}
+
+// Option configures optional Client behaviour at construction time.
+type Option func(*Client)
+
+// WithReconnectBackoff sets the initial delay before retrying a failed dial
+// (it doubles up to a 30s ceiling on each further failure). After a dropped
+// connection the first redial is immediate. The default is 1s.
+func WithReconnectBackoff(initial time.Duration) Option {
+	return func(c *Client) { c.reconnectBackoff = initial }
+}
+
+// NewClient starts connecting to addr in the background and returns
+// immediately; callers can start issuing queries right away, but until the
+// first connection succeeds they fail at once with a "not connected" error.
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		addr:             addr,
+		inflight:         make(map[string][]chan string),
+		events:           make(chan Event, 32),
+		reconnectBackoff: time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	go c.connectLoop()
+	return c
+}
+
+// Subscribe returns a channel of unsolicited Denon status events.
+func (c *Client) Subscribe() <-chan Event {
+	return c.events
+}
+
+// Watch implements sync.Watcher, translating raw protocol frames into
+// generic volume events for the sync engine. Frames that aren't a volume or
+// mute change (power, input select, surround mode, ...) are dropped.
+func (c *Client) Watch() <-chan syncengine.VolumeEvent {
+	out := make(chan syncengine.VolumeEvent, 32)
+	go func() {
+		defer close(out)
+		for event := range c.Subscribe() {
+			switch {
+			case event.Raw == "MUON":
+				out <- syncengine.VolumeEvent{Muted: true}
+			case event.Raw == "MUOFF":
+				out <- syncengine.VolumeEvent{Muted: false}
+			case strings.HasPrefix(event.Raw, "MV") && !strings.HasPrefix(event.Raw, "MVMAX"):
+				if v, err := strconv.Atoi(strings.TrimPrefix(event.Raw, "MV")); err == nil {
+					out <- syncengine.VolumeEvent{Volume: v}
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// connectLoop dials the receiver and, on success, reads from it until the
+// connection drops, then redials with exponential backoff. It never returns.
+func (c *Client) connectLoop() {
+	backoff := c.reconnectBackoff
+	for {
+		conn, err := net.DialTimeout("tcp", c.addr, 3*time.Second)
+		if err != nil {
+			fmt.Println("Denon connect error:", err)
+			time.Sleep(backoff)
+			if backoff < 30*time.Second {
+				backoff *= 2
+				if backoff > 30*time.Second {
+					backoff = 30 * time.Second
+				}
+			}
+			continue
+		}
+		backoff = c.reconnectBackoff
+
+		c.mu.Lock()
+		c.conn = conn
+		c.mu.Unlock()
+
+		fmt.Println("Denon connected at", c.addr)
+		c.readLoop(conn)
+
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+	}
+}
+
+// readLoop consumes \r-terminated frames until the connection breaks.
+func (c *Client) readLoop(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\r')
+		if err != nil {
+			fmt.Println("Denon read error:", err)
+			conn.Close()
+			return
+		}
+		c.dispatch(strings.TrimSpace(line))
+	}
+}
+
+// dispatch hands a frame to the oldest in-flight query waiting on its
+// command prefix, or publishes it as an unsolicited event if none is. Waiters
+// on the same prefix are served FIFO, since e.g. pollStatus and the sync
+// engine both query "MV?"/"PW?" on their own schedules and can have more than
+// one outstanding at once.
+func (c *Client) dispatch(line string) {
+	if line == "" {
+		return
+	}
+	prefix := commandPrefix(line)
+
+	c.inflightMu.Lock()
+	var ch chan string
+	waiting := len(c.inflight[prefix]) > 0
+	if waiting {
+		ch = c.inflight[prefix][0]
+		c.inflight[prefix] = c.inflight[prefix][1:]
+		if len(c.inflight[prefix]) == 0 {
+			delete(c.inflight, prefix)
+		}
+	}
+	c.inflightMu.Unlock()
+
+	if waiting {
+		ch <- line
+		return
+	}
+
+	select {
+	case c.events <- Event{Raw: line}:
+	default:
+		// Event buffer full; drop rather than block the reader.
+	}
+}
+
+// commandPrefix returns the two-character command code a frame is keyed on,
+// e.g. "MV" for both "MV45" and "MV?", "PW" for "PWON", "Z2" for "Z2ON".
+// Input shorter than two characters is returned unchanged.
+func commandPrefix(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	return s[:2]
+}
+
+// send writes a raw command, holding the connection mutex for the whole
+// write (not just the conn pointer read) so concurrent callers can't
+// interleave bytes on the wire.
+func (c *Client) send(command string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return fmt.Errorf("denon: not connected")
+	}
+	_, err := c.conn.Write([]byte(command + "\r\n"))
+	return err
+}
+
+// query sends a command and waits for the reply whose prefix matches the
+// command's own prefix, so e.g. "MV?" is answered by the next "MV\d+" frame
+// rather than whatever the reader happens to read next. Concurrent queries
+// for the same prefix (e.g. the admin API's pollStatus and the sync engine
+// both issuing "MV?" on their own schedules) each get their own slot and are
+// answered in the order their commands were sent.
+func (c *Client) query(command string, timeout time.Duration) (string, error) {
+	prefix := commandPrefix(strings.TrimSuffix(command, "?"))
+
+	ch := make(chan string, 1)
+	c.inflightMu.Lock()
+	c.inflight[prefix] = append(c.inflight[prefix], ch)
+	c.inflightMu.Unlock()
+
+	if err := c.send(command); err != nil {
+		c.removeWaiter(prefix, ch)
+		return "", err
+	}
+
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-time.After(timeout):
+		c.removeWaiter(prefix, ch)
+		return "", fmt.Errorf("denon: timed out waiting for reply to %q", command)
+	}
+}
+
+// removeWaiter drops ch from prefix's waiter queue, e.g. after a send error
+// or timeout, so a later reply for that prefix doesn't get delivered to a
+// caller that's no longer listening.
+func (c *Client) removeWaiter(prefix string, ch chan string) {
+	c.inflightMu.Lock()
+	defer c.inflightMu.Unlock()
+	waiters := c.inflight[prefix]
+	for i, w := range waiters {
+		if w == ch {
+			c.inflight[prefix] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(c.inflight[prefix]) == 0 {
+		delete(c.inflight, prefix)
+	}
+}
+
+// Power reports whether the Denon main zone is powered on.
+func (c *Client) Power() (bool, error) {
+	reply, err := c.query("PW?", 2*time.Second)
+	if err != nil {
+		return false, err
+	}
+	return reply == "PWON", nil
+}
+
+// Volume reports the Denon main zone volume.
+func (c *Client) Volume() (int, error) {
+	reply, err := c.query("MV?", 2*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	reply = strings.TrimPrefix(reply, "MV")
+	volume, err := strconv.Atoi(reply)
+	if err != nil {
+		return 0, fmt.Errorf("denon: unexpected volume reply %q", reply)
+	}
+	return volume, nil
+}
+
+// Muted reports whether the Denon main zone is muted.
+func (c *Client) Muted() (bool, error) {
+	reply, err := c.query("MU?", 2*time.Second)
+	if err != nil {
+		return false, err
+	}
+	return reply == "MUON", nil
+}
+
+// SetVolume sets the Denon main zone volume. The protocol doesn't reply to
+// this command, so it's fire-and-forget.
+func (c *Client) SetVolume(volume int) error {
+	return c.send(fmt.Sprintf("MV%02d", volume))
+}
+
+// SetSource switches the main zone's input selector, e.g. "GAME" or "NET",
+// fire-and-forget like SetVolume.
+func (c *Client) SetSource(source string) error {
+	return c.send("SI" + source)
+}
+
+// IsOn implements sync.VolumeSource/VolumeSink; it's an alias for Power.
+func (c *Client) IsOn() (bool, error) {
+	return c.Power()
+}
+
+// GetVolume implements sync.VolumeSource/VolumeSink, combining Volume and Muted.
+func (c *Client) GetVolume() (int, bool, error) {
+	volume, err := c.Volume()
+	if err != nil {
+		return 0, false, err
+	}
+	muted, err := c.Muted()
+	if err != nil {
+		return volume, false, err
+	}
+	return volume, muted, nil
+}
@@ -0,0 +1,58 @@
+package denon
+
+import "testing"
+
+func TestCommandPrefix(t *testing.T) {
+	cases := map[string]string{
+		"MV45": "MV",
+		"MV?":  "MV",
+		"":     "",
+	}
+	for input, want := range cases {
+		if got := commandPrefix(input); got != want {
+			t.Errorf("commandPrefix(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDispatchServesWaitersFIFO(t *testing.T) {
+	c := &Client{
+		inflight: make(map[string][]chan string),
+		events:   make(chan Event, 4),
+	}
+
+	first := make(chan string, 1)
+	second := make(chan string, 1)
+	c.inflight["MV"] = []chan string{first, second}
+
+	c.dispatch("MV45")
+	c.dispatch("MV50")
+
+	if reply := <-first; reply != "MV45" {
+		t.Errorf("first waiter got %q, want MV45", reply)
+	}
+	if reply := <-second; reply != "MV50" {
+		t.Errorf("second waiter got %q, want MV50", reply)
+	}
+	if len(c.inflight["MV"]) != 0 {
+		t.Errorf("inflight[MV] not drained: %v", c.inflight["MV"])
+	}
+}
+
+func TestDispatchPublishesUnsolicitedEvent(t *testing.T) {
+	c := &Client{
+		inflight: make(map[string][]chan string),
+		events:   make(chan Event, 4),
+	}
+
+	c.dispatch("Z2ON")
+
+	select {
+	case event := <-c.events:
+		if event.Raw != "Z2ON" {
+			t.Errorf("event.Raw = %q, want Z2ON", event.Raw)
+		}
+	default:
+		t.Fatal("expected an unsolicited event, got none")
+	}
+}
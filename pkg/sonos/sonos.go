@@ -0,0 +1,113 @@
+// Package sonos is a VolumeSink for Sonos players, controlled over their
+// UPnP RenderingControl service - a second sink option for people whose
+// audio chain isn't a Denon AVR.
+package sonos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const renderingControlNS = "urn:schemas-upnp-org:service:RenderingControl:1"
+
+// Client controls a single Sonos player's volume via SOAP calls to its
+// RenderingControl service.
+type Client struct {
+	// ControlURL is the player's RenderingControl control endpoint, e.g.
+	// "http://192.168.1.50:1400/MediaRenderer/RenderingControl/Control".
+	ControlURL string
+}
+
+// NewClient returns a Client for the player at controlURL.
+func NewClient(controlURL string) *Client {
+	return &Client{ControlURL: controlURL}
+}
+
+// IsOn always reports true: unlike a TV or AVR, a reachable Sonos player
+// doesn't have a separate power state to query.
+func (c *Client) IsOn() (bool, error) {
+	return true, nil
+}
+
+// GetVolume returns the player's volume and whether its master channel is muted.
+func (c *Client) GetVolume() (int, bool, error) {
+	volumeResp, err := c.soapCall("GetVolume", "<Channel>Master</Channel>")
+	if err != nil {
+		return 0, false, err
+	}
+	volume, err := extractSOAPValue(volumeResp, "CurrentVolume")
+	if err != nil {
+		return 0, false, err
+	}
+
+	muteResp, err := c.soapCall("GetMute", "<Channel>Master</Channel>")
+	if err != nil {
+		return volume, false, err
+	}
+	mute, err := extractSOAPValue(muteResp, "CurrentMute")
+	if err != nil {
+		return volume, false, err
+	}
+
+	return volume, mute != 0, nil
+}
+
+// SetVolume sets the player's master channel volume (0-100).
+func (c *Client) SetVolume(volume int) error {
+	_, err := c.soapCall("SetVolume", fmt.Sprintf("<Channel>Master</Channel><DesiredVolume>%d</DesiredVolume>", volume))
+	return err
+}
+
+// soapCall invokes a RenderingControl action and returns the raw response body.
+func (c *Client) soapCall(action, args string) ([]byte, error) {
+	envelope := fmt.Sprintf(`<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+  <s:Body>
+    <u:%s xmlns:u="%s">
+      <InstanceID>0</InstanceID>
+      %s
+    </u:%s>
+  </s:Body>
+</s:Envelope>`, action, renderingControlNS, args, action)
+
+	req, err := http.NewRequest("POST", c.ControlURL, bytes.NewBufferString(envelope))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPACTION", fmt.Sprintf(`"%s#%s"`, renderingControlNS, action))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sonos: %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// extractSOAPValue pulls a single <tag>value</tag> integer out of a SOAP
+// response body - good enough for the handful of scalar fields we care
+// about without pulling in a full SOAP/XML client.
+func extractSOAPValue(body []byte, tag string) (int, error) {
+	open, close := "<"+tag+">", "</"+tag+">"
+
+	start := bytes.Index(body, []byte(open))
+	if start == -1 {
+		return 0, fmt.Errorf("sonos: %s not found in response", tag)
+	}
+	start += len(open)
+
+	end := bytes.Index(body[start:], []byte(close))
+	if end == -1 {
+		return 0, fmt.Errorf("sonos: %s not terminated in response", tag)
+	}
+
+	var value int
+	if _, err := fmt.Sscanf(string(body[start:start+end]), "%d", &value); err != nil {
+		return 0, fmt.Errorf("sonos: invalid %s value: %w", tag, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"math"
+	"sync"
+)
+
+// CapCurve caps the source volume at Max, replacing the old hardcoded
+// "if volume > 40" check with a configurable per-sink limit.
+type CapCurve struct {
+	Max int
+}
+
+// Map implements Curve.
+func (c CapCurve) Map(v int) int {
+	if v > c.Max {
+		return c.Max
+	}
+	return v
+}
+
+// MutableCapCurve is a CapCurve whose limit can be changed while the engine
+// is running, e.g. from an admin API, without tearing down and recreating
+// the SinkConfig.
+type MutableCapCurve struct {
+	mu  sync.Mutex
+	max int
+}
+
+// NewMutableCapCurve returns a MutableCapCurve capping volume at max.
+func NewMutableCapCurve(max int) *MutableCapCurve {
+	return &MutableCapCurve{max: max}
+}
+
+// Map implements Curve.
+func (c *MutableCapCurve) Map(v int) int {
+	max := c.Max()
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// SetMax updates the cap.
+func (c *MutableCapCurve) SetMax(max int) {
+	c.mu.Lock()
+	c.max = max
+	c.mu.Unlock()
+}
+
+// Max returns the current cap.
+func (c *MutableCapCurve) Max() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.max
+}
+
+// FuncCurve adapts a plain function to the Curve interface, for callers
+// whose mapping logic doesn't warrant its own named type - e.g. an
+// input-profile lookup that also applies a dB trim.
+type FuncCurve func(sourceVolume int) int
+
+// Map implements Curve.
+func (f FuncCurve) Map(v int) int {
+	return f(v)
+}
+
+// LinearCurve rescales volume linearly from the source's range onto the
+// sink's range, e.g. mapping a TV's 0-100 onto an AVR's 0-98. It is a single
+// straight line from 0 to SourceMax; it has no intermediate breakpoints.
+type LinearCurve struct {
+	SourceMax int
+	SinkMax   int
+}
+
+// Map implements Curve.
+func (c LinearCurve) Map(v int) int {
+	if c.SourceMax <= 0 {
+		return v
+	}
+	return v * c.SinkMax / c.SourceMax
+}
+
+// LogCurve rescales volume logarithmically, for sinks whose perceived
+// loudness doesn't track the source linearly.
+type LogCurve struct {
+	SourceMax int
+	SinkMax   int
+}
+
+// Map implements Curve.
+func (c LogCurve) Map(v int) int {
+	if v <= 0 {
+		return 0
+	}
+	if c.SourceMax <= 0 {
+		return v
+	}
+	ratio := float64(v) / float64(c.SourceMax)
+	scaled := math.Log1p(ratio*(math.E-1)) * float64(c.SinkMax)
+	return int(math.Round(scaled))
+}
@@ -0,0 +1,57 @@
+package sync
+
+import "testing"
+
+func TestCapCurve(t *testing.T) {
+	c := CapCurve{Max: 40}
+	if got := c.Map(30); got != 30 {
+		t.Errorf("Map(30) = %d, want 30", got)
+	}
+	if got := c.Map(80); got != 40 {
+		t.Errorf("Map(80) = %d, want 40", got)
+	}
+}
+
+func TestMutableCapCurveSetMax(t *testing.T) {
+	c := NewMutableCapCurve(40)
+	if got := c.Map(80); got != 40 {
+		t.Errorf("Map(80) = %d, want 40", got)
+	}
+	c.SetMax(20)
+	if got := c.Map(80); got != 20 {
+		t.Errorf("after SetMax(20), Map(80) = %d, want 20", got)
+	}
+}
+
+func TestLinearCurve(t *testing.T) {
+	c := LinearCurve{SourceMax: 100, SinkMax: 98}
+	if got := c.Map(50); got != 49 {
+		t.Errorf("Map(50) = %d, want 49", got)
+	}
+	if got := c.Map(0); got != 0 {
+		t.Errorf("Map(0) = %d, want 0", got)
+	}
+
+	zero := LinearCurve{}
+	if got := zero.Map(50); got != 50 {
+		t.Errorf("Map with SourceMax 0 should pass through unchanged, got %d", got)
+	}
+}
+
+func TestLogCurve(t *testing.T) {
+	c := LogCurve{SourceMax: 100, SinkMax: 100}
+	if got := c.Map(0); got != 0 {
+		t.Errorf("Map(0) = %d, want 0", got)
+	}
+	if got := c.Map(100); got != 100 {
+		t.Errorf("Map(SourceMax) = %d, want SinkMax (100)", got)
+	}
+	if mid := c.Map(50); mid <= 0 || mid >= 100 {
+		t.Errorf("Map(50) = %d, want strictly between 0 and 100", mid)
+	}
+
+	zero := LogCurve{}
+	if got := zero.Map(50); got != 50 {
+		t.Errorf("Map with SourceMax 0 should pass through unchanged, got %d", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package sync is a generic volume-mirroring engine over pluggable
+// VolumeSource and VolumeSink implementations (Bravia, Denon, Sonos, ...), so
+// the mirroring logic doesn't need to know which devices it's actually
+// talking to.
+package sync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// VolumeEvent is a state change pushed by a source or sink that implements Watcher.
+type VolumeEvent struct {
+	Volume int
+	Muted  bool
+}
+
+// VolumeSource is something whose volume gets mirrored elsewhere, e.g. a TV.
+type VolumeSource interface {
+	GetVolume() (int, bool, error) // volume, muted, error
+	IsOn() (bool, error)
+}
+
+// VolumeSink is something a source's volume gets mirrored to, e.g. an AVR.
+type VolumeSink interface {
+	VolumeSource
+	SetVolume(int) error
+}
+
+// Watcher is implemented by sources that can push volume changes instead of
+// needing to be polled for them.
+type Watcher interface {
+	Watch() <-chan VolumeEvent
+}
+
+// Curve maps a source volume (0-100) onto a value appropriate for a specific
+// sink - a hard cap, a rescale between different volume ranges, a
+// logarithmic taper, and so on.
+type Curve interface {
+	Map(sourceVolume int) int
+}
+
+// SinkConfig pairs a sink with the curve used to map the source's volume
+// onto it. A nil Curve passes the source volume through unchanged.
+type SinkConfig struct {
+	Sink  VolumeSink
+	Curve Curve
+}
+
+// Engine mirrors Source's volume (and mute state) onto one or more Sinks.
+type Engine struct {
+	Source VolumeSource
+	Sinks  []SinkConfig
+
+	mu           sync.Mutex
+	pollInterval time.Duration
+	paused       bool
+}
+
+// New creates an Engine with a sensible default poll interval.
+func New(source VolumeSource, sinks ...SinkConfig) *Engine {
+	return &Engine{Source: source, Sinks: sinks, pollInterval: time.Second}
+}
+
+// SetPaused pauses or resumes mirroring without stopping Run, so e.g. an
+// admin API can let a user take manual control temporarily.
+func (e *Engine) SetPaused(paused bool) {
+	e.mu.Lock()
+	e.paused = paused
+	e.mu.Unlock()
+}
+
+// Paused reports whether mirroring is currently paused.
+func (e *Engine) Paused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
+
+// SetPollInterval changes how often Run checks the source when it's not
+// being woken up by a Watcher event, effective from the next cycle - so a
+// config hot-reload can tighten or loosen polling without restarting Run.
+func (e *Engine) SetPollInterval(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	e.mu.Lock()
+	e.pollInterval = d
+	e.mu.Unlock()
+}
+
+func (e *Engine) PollInterval() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pollInterval
+}
+
+// Run mirrors the source's volume onto every sink. It never returns: errors
+// are logged by each pass and the next pass retries. If Source implements
+// Watcher, its events wake the loop immediately instead of waiting out the
+// full poll interval.
+func (e *Engine) Run() error {
+	trigger := make(chan struct{}, 1)
+	if w, ok := e.Source.(Watcher); ok {
+		go func() {
+			for range w.Watch() {
+				select {
+				case trigger <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+
+	for {
+		e.syncOnce()
+		select {
+		case <-time.After(e.PollInterval()):
+		case <-trigger:
+		}
+	}
+}
+
+// syncOnce runs a single mirror pass, logging (rather than returning) any
+// per-device error so one unreachable sink doesn't stop the others.
+func (e *Engine) syncOnce() {
+	if e.Paused() {
+		return
+	}
+
+	on, err := e.Source.IsOn()
+	if err != nil {
+		fmt.Println("Error checking source power:", err)
+		return
+	}
+	if !on {
+		fmt.Println("Source is not ON.")
+		return
+	}
+
+	volume, muted, err := e.Source.GetVolume()
+	if err != nil {
+		fmt.Println("Error getting source volume:", err)
+		return
+	}
+	if muted {
+		volume = 0
+	}
+
+	for _, sc := range e.Sinks {
+		e.syncSink(sc, volume)
+	}
+}
+
+func (e *Engine) syncSink(sc SinkConfig, sourceVolume int) {
+	on, err := sc.Sink.IsOn()
+	if err != nil {
+		fmt.Println("Error checking sink power:", err)
+		return
+	}
+	if !on {
+		fmt.Println("Sink is OFF.")
+		return
+	}
+
+	target := sourceVolume
+	if sc.Curve != nil {
+		target = sc.Curve.Map(sourceVolume)
+	}
+
+	current, _, err := sc.Sink.GetVolume()
+	if err != nil {
+		fmt.Println("Error getting sink volume:", err)
+		return
+	}
+
+	if current != target {
+		fmt.Printf("--> Setting sink volume to %d\n", target)
+		if err := sc.Sink.SetVolume(target); err != nil {
+			fmt.Println("Error setting sink volume:", err)
+		}
+	}
+}
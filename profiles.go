@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/bravia"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/config"
+	"github.com/gpatola/Bravia2DenonVolumeController/pkg/denon"
+	syncengine "github.com/gpatola/Bravia2DenonVolumeController/pkg/sync"
+)
+
+// profileCurve applies the currently active input profile's volume cap and
+// dB trim, falling back to a plain MutableCapCurve (the same one the admin
+// API's PUT /config edits) when no profile matches the current input.
+type profileCurve struct {
+	mu       sync.Mutex
+	active   *config.InputProfile
+	fallback *syncengine.MutableCapCurve
+}
+
+func newProfileCurve(fallback *syncengine.MutableCapCurve) *profileCurve {
+	return &profileCurve{fallback: fallback}
+}
+
+// Map implements syncengine.Curve. A source volume of 0 means the engine has
+// muted (see sync.Engine.syncOnce), so it's passed straight through - adding
+// a profile's trim here would turn that silence back into an audible level.
+func (c *profileCurve) Map(v int) int {
+	if v == 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	profile := c.active
+	c.mu.Unlock()
+
+	if profile == nil {
+		return c.fallback.Map(v)
+	}
+
+	adjusted := v + int(math.Round(profile.TrimDB))
+	if adjusted > profile.MaxVolume {
+		adjusted = profile.MaxVolume
+	}
+	if adjusted < 0 {
+		adjusted = 0
+	}
+	return adjusted
+}
+
+func (c *profileCurve) setActive(p *config.InputProfile) {
+	c.mu.Lock()
+	c.active = p
+	c.mu.Unlock()
+}
+
+// watchInputProfiles polls the Bravia's active input/app and, whenever it
+// changes, switches the profileCurve (and the Denon's selected source) to
+// match - so e.g. HDMI 2 can cap volume differently than the Netflix app.
+// It re-reads device config from manager on every cycle, so poll-interval
+// changes take effect without restarting; profile edits take effect the next
+// time the input changes.
+func watchInputProfiles(manager *config.Manager, deviceName string, tv *bravia.Client, avr *denon.Client, profiles *profileCurve) {
+	lastInput := ""
+	for {
+		cfg := manager.Current()
+		time.Sleep(cfg.PollInterval.Duration)
+
+		device, ok := deviceByName(cfg, deviceName)
+		if !ok {
+			continue
+		}
+
+		input, err := tv.CurrentInput()
+		if err != nil {
+			continue
+		}
+		if input == lastInput {
+			continue
+		}
+		lastInput = input
+
+		profile := device.ProfileFor(input)
+		profiles.setActive(profile)
+
+		switch {
+		case profile == nil:
+			fmt.Println("Input changed to", input, "(no matching profile)")
+		case profile.DenonSource == "":
+			fmt.Println("Input changed to", input, "-> profile", profile.Input, "(no Denon source override)")
+		default:
+			fmt.Println("Input changed to", input, "-> Denon source", profile.DenonSource)
+			if err := avr.SetSource(profile.DenonSource); err != nil {
+				fmt.Println("Error switching Denon source:", err)
+			}
+		}
+	}
+}
+
+func deviceByName(cfg config.Config, name string) (config.DevicePair, bool) {
+	for _, d := range cfg.Devices {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return config.DevicePair{}, false
+}